@@ -0,0 +1,218 @@
+package orderedpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoFunctions is returned by MapFastest when fns is empty — there is
+// nothing to race, so reporting a fabricated success would be misleading.
+var ErrNoFunctions = errors.New("orderedpool: no functions provided")
+
+// MapFastest dispatches each input element to every fn in fns concurrently
+// and keeps the first successful result (or the last error if all fail),
+// while still emitting results on the output channel in input order. It is
+// useful for redundant backends / hedged requests. opt.TaskTimeout bounds the
+// whole race for an element, not any single attempt. opt.Retry, if set,
+// retries the entire race (all of fns again) on a retryable error, and
+// opt.Observer receives the same per-element callbacks MapOrdered reports.
+func MapFastest[T any, R any](
+	ctx context.Context,
+	input <-chan T,
+	fns []func(context.Context, T) (R, error),
+	opt Options,
+) <-chan Result[R] {
+	if opt.Workers <= 0 {
+		opt.Workers = 1
+	}
+	if opt.MaxInFlight < opt.Workers {
+		opt.MaxInFlight = opt.Workers
+	}
+
+	output := make(chan Result[R], opt.MaxInFlight)
+
+	go func() {
+		defer close(output)
+
+		// workCtx lets us stop the feeder and workers the moment we return
+		// early (e.g. on EarlyStopN) instead of leaking them until the
+		// caller's ctx is eventually cancelled.
+		workCtx, cancelWork := context.WithCancel(ctx)
+		defer cancelWork()
+
+		taskChan := make(chan indexedTask[T], opt.MaxInFlight)
+		resultChan := make(chan indexedResult[R], opt.MaxInFlight)
+
+		var wg sync.WaitGroup
+		for i := 0; i < opt.Workers; i++ {
+			wg.Add(1)
+			go fastestWorker(workCtx, fns, opt, taskChan, resultChan, &wg)
+		}
+
+		go func() {
+			wg.Wait()
+			close(resultChan)
+		}()
+
+		go func() {
+			defer close(taskChan)
+
+			index := 0
+			for val := range input {
+				select {
+				case <-workCtx.Done():
+					return
+				case taskChan <- indexedTask[T]{index: index, value: val}:
+					index++
+				}
+			}
+		}()
+
+		buffer := make(map[int]Result[R])
+		nextIndex := 0
+		successCount := 0
+
+		for res := range resultChan {
+			buffer[res.index] = res.res
+
+			if opt.Observer != nil {
+				opt.Observer.OnQueueDepth(len(taskChan), len(buffer))
+			}
+
+			for {
+				if item, ok := buffer[nextIndex]; ok {
+					delete(buffer, nextIndex)
+					select {
+					case <-workCtx.Done():
+						return
+					case output <- item:
+						if item.Err == nil {
+							successCount++
+							if opt.EarlyStopN > 0 && successCount >= opt.EarlyStopN {
+								return
+							}
+						}
+					}
+					nextIndex++
+				} else {
+					break
+				}
+			}
+		}
+	}()
+
+	return output
+}
+
+func fastestWorker[T any, R any](
+	ctx context.Context,
+	fns []func(context.Context, T) (R, error),
+	opt Options,
+	taskChan <-chan indexedTask[T],
+	resultChan chan<- indexedResult[R],
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task, ok := <-taskChan:
+			if !ok {
+				return
+			}
+
+			taskCtx := ctx
+			var cancel context.CancelFunc
+			if opt.TaskTimeout > 0 {
+				taskCtx, cancel = context.WithTimeout(ctx, opt.TaskTimeout)
+			}
+
+			race := func(raceCtx context.Context, v T) (R, error) {
+				res := raceFns(raceCtx, fns, opt, v, task.index)
+				return res.Value, res.Err
+			}
+
+			val, err, attempts := runWithRetry(ctx, taskCtx, race, opt, task.value, task.index)
+
+			if cancel != nil {
+				cancel()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case resultChan <- indexedResult[R]{index: task.index, res: Result[R]{Value: val, Err: err, Attempts: attempts}}:
+			}
+		}
+	}
+}
+
+// raceFns runs every fn in fns against value under raceCtx and returns as
+// soon as one succeeds, cancelling the rest. The caller (fastestWorker, via
+// runWithRetry) is responsible for bounding raceCtx with opt.TaskTimeout and
+// for retrying the whole race on failure; raceFns itself races exactly once.
+func raceFns[T any, R any](raceCtx context.Context, fns []func(context.Context, T) (R, error), opt Options, value T, index int) Result[R] {
+	if len(fns) == 0 {
+		return Result[R]{Err: ErrNoFunctions}
+	}
+
+	type attempt struct {
+		value R
+		err   error
+	}
+
+	results := make(chan attempt, len(fns))
+	cancels := make([]context.CancelFunc, len(fns))
+
+	for i, fn := range fns {
+		attemptCtx, cancel := context.WithCancel(raceCtx)
+		cancels[i] = cancel
+
+		go func(fn func(context.Context, T) (R, error), attemptCtx context.Context) {
+			defer func() {
+				if r := recover(); r != nil {
+					if opt.Observer != nil {
+						opt.Observer.OnPanic(index, r)
+					}
+					if opt.PanicAsError {
+						results <- attempt{err: &PanicError{Panic: r}}
+						return
+					}
+					panic(r)
+				}
+			}()
+			v, err := fn(attemptCtx, value)
+			results <- attempt{value: v, err: err}
+		}(fn, attemptCtx)
+	}
+
+	cancelAll := func() {
+		for _, c := range cancels {
+			c()
+		}
+	}
+
+	var lastErr error
+	for i := 0; i < len(fns); i++ {
+		select {
+		case <-raceCtx.Done():
+			cancelAll()
+			if lastErr == nil {
+				lastErr = raceCtx.Err()
+			}
+			return Result[R]{Err: lastErr}
+		case r := <-results:
+			if r.err == nil {
+				cancelAll()
+				return Result[R]{Value: r.value}
+			}
+			lastErr = r.err
+		}
+	}
+
+	cancelAll()
+	return Result[R]{Err: lastErr}
+}
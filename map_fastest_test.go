@@ -0,0 +1,225 @@
+package orderedpool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapFastestNoFunctionsIsAnError(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){}, Options{Workers: 1})
+
+	r := <-out
+	if r.Err != ErrNoFunctions {
+		t.Fatalf("expected ErrNoFunctions, got %+v", r)
+	}
+}
+
+func TestMapFastestPicksWinner(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 2)
+	in <- 1
+	in <- 2
+	close(in)
+
+	slow := func(_ context.Context, x int) (int, error) {
+		time.Sleep(50 * time.Millisecond)
+		return x * 100, nil
+	}
+	fast := func(_ context.Context, x int) (int, error) {
+		return x * 2, nil
+	}
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){slow, fast}, Options{Workers: 2})
+
+	expected := []int{2, 4}
+	i := 0
+	for r := range out {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.Value != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], r.Value)
+		}
+		i++
+	}
+}
+
+func TestMapFastestAllFail(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+
+	fnA := func(_ context.Context, x int) (int, error) { return 0, errA }
+	fnB := func(_ context.Context, x int) (int, error) { return 0, errB }
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){fnA, fnB}, Options{Workers: 1})
+
+	r := <-out
+	if r.Err == nil {
+		t.Fatal("expected an error when all attempts fail")
+	}
+}
+
+func TestMapFastestCancelsLosers(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	loserCancelled := make(chan struct{}, 1)
+
+	winner := func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}
+	loser := func(ctx context.Context, x int) (int, error) {
+		<-ctx.Done()
+		loserCancelled <- struct{}{}
+		return 0, ctx.Err()
+	}
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){winner, loser}, Options{Workers: 1})
+
+	r := <-out
+	if r.Err != nil || r.Value != 1 {
+		t.Fatalf("expected winning value 1, got %+v", r)
+	}
+
+	select {
+	case <-loserCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("loser was never cancelled")
+	}
+}
+
+func TestMapFastestHonorsRetry(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	var calls int32
+	errTransient := errors.New("transient")
+
+	fn := func(_ context.Context, x int) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n < 3 {
+			return 0, errTransient
+		}
+		return x, nil
+	}
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){fn}, Options{
+		Workers: 1,
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			Multiplier:     2,
+		},
+	})
+
+	r := <-out
+	if r.Err != nil {
+		t.Fatalf("expected eventual success, got %v", r.Err)
+	}
+	if r.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", r.Attempts)
+	}
+}
+
+func TestMapFastestReportsToObserver(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 2)
+	in <- 1
+	in <- 2
+	close(in)
+
+	obs := &recordingObserver{}
+
+	fn := func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){fn}, Options{
+		Workers:  1,
+		Observer: obs,
+	})
+
+	for range out {
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.starts) != 2 || len(obs.ends) != 2 {
+		t.Fatalf("expected 2 starts/ends, got %d/%d", len(obs.starts), len(obs.ends))
+	}
+}
+
+func TestMapFastestEarlyStopDoesNotLeakGoroutines(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1000)
+	for i := 0; i < 1000; i++ {
+		in <- i
+	}
+	close(in)
+
+	runtime.Gosched()
+	before := runtime.NumGoroutine()
+
+	fn := func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){fn}, Options{
+		Workers:    4,
+		EarlyStopN: 2,
+	})
+
+	for range out {
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+1 {
+		t.Fatalf("expected feeder/workers to stop after EarlyStopN: before=%d after=%d", before, after)
+	}
+}
+
+func TestMapFastestTaskTimeoutBoundsRace(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	slow := func(ctx context.Context, x int) (int, error) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return x, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	out := MapFastest(ctx, in, []func(context.Context, int) (int, error){slow}, Options{
+		Workers:     1,
+		TaskTimeout: 10 * time.Millisecond,
+	})
+
+	r := <-out
+	if r.Err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
@@ -2,13 +2,17 @@ package orderedpool
 
 import (
 	"context"
+	"errors"
+	"math"
+	"math/rand"
 	"sync"
 	"time"
 )
 
 type Result[R any] struct {
-	Value R
-	Err   error
+	Value    R
+	Err      error
+	Attempts int
 }
 
 type Options struct {
@@ -17,6 +21,20 @@ type Options struct {
 	EarlyStopN   int
 	PanicAsError bool
 	TaskTimeout  time.Duration
+	Retry        RetryPolicy
+	Observer     Observer
+}
+
+// RetryPolicy controls automatic retries of a task on retryable errors, with
+// capped exponential backoff. The zero value disables retries (a task runs
+// exactly once).
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         bool
+	Retryable      func(error) bool
 }
 
 type indexedTask[T any] struct {
@@ -47,6 +65,12 @@ func MapOrdered[T any, R any](
 	go func() {
 		defer close(output)
 
+		// workCtx lets us stop the feeder and workers the moment we return
+		// early (e.g. on EarlyStopN) instead of leaking them until the
+		// caller's ctx is eventually cancelled.
+		workCtx, cancelWork := context.WithCancel(ctx)
+		defer cancelWork()
+
 		// каналы для задач и результатов
 		taskChan := make(chan indexedTask[T], opt.MaxInFlight)
 		resultChan := make(chan indexedResult[R], opt.MaxInFlight)
@@ -55,7 +79,7 @@ func MapOrdered[T any, R any](
 		var wg sync.WaitGroup
 		for i := 0; i < opt.Workers; i++ {
 			wg.Add(1)
-			go worker(ctx, fn, opt, taskChan, resultChan, &wg)
+			go worker(workCtx, fn, opt, taskChan, resultChan, &wg)
 		}
 
 		// goroutine для закрытия каналов
@@ -71,7 +95,7 @@ func MapOrdered[T any, R any](
 			index := 0
 			for val := range input {
 				select {
-				case <-ctx.Done():
+				case <-workCtx.Done():
 					return
 				case taskChan <- indexedTask[T]{index: index, value: val}:
 					index++
@@ -87,12 +111,16 @@ func MapOrdered[T any, R any](
 		for res := range resultChan {
 			buffer[res.index] = res.res
 
+			if opt.Observer != nil {
+				opt.Observer.OnQueueDepth(len(taskChan), len(buffer))
+			}
+
 			// публикация в порядке
 			for {
 				if item, ok := buffer[nextIndex]; ok {
 					delete(buffer, nextIndex)
 					select {
-					case <-ctx.Done():
+					case <-workCtx.Done():
 						return
 					case output <- item:
 						if item.Err == nil {
@@ -132,28 +160,14 @@ func worker[T any, R any](
 				return
 			}
 
-			// контекст задачи
+			// контекст задачи — TaskTimeout это бюджет на все попытки целиком
 			taskCtx := ctx
 			var cancel context.CancelFunc
 			if opt.TaskTimeout > 0 {
 				taskCtx, cancel = context.WithTimeout(ctx, opt.TaskTimeout)
 			}
 
-			var res R
-			var err error
-
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						if opt.PanicAsError {
-							err = &PanicError{Panic: r}
-						} else {
-							panic(r)
-						}
-					}
-				}()
-				res, err = fn(taskCtx, task.value)
-			}()
+			res, err, attempts := runWithRetry(ctx, taskCtx, fn, opt, task.value, task.index)
 
 			if cancel != nil {
 				cancel()
@@ -162,7 +176,7 @@ func worker[T any, R any](
 			select {
 			case <-ctx.Done():
 				return
-			case resultChan <- indexedResult[R]{index: task.index, res: Result[R]{Value: res, Err: err}}:
+			case resultChan <- indexedResult[R]{index: task.index, res: Result[R]{Value: res, Err: err, Attempts: attempts}}:
 			}
 		}
 	}
@@ -175,3 +189,107 @@ type PanicError struct {
 func (e *PanicError) Error() string {
 	return "panic occurred"
 }
+
+// runWithRetry invokes fn against value, retrying on retryable errors per
+// opt.Retry with capped exponential backoff. taskCtx (bounded by
+// opt.TaskTimeout, if set) is the overall budget for every attempt combined;
+// ctx is the outer cancellation signal. It returns the last value/error seen
+// and the number of attempts made.
+func runWithRetry[T any, R any](
+	ctx context.Context,
+	taskCtx context.Context,
+	fn func(context.Context, T) (R, error),
+	opt Options,
+	value T,
+	index int,
+) (R, error, int) {
+	maxAttempts := opt.Retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	if opt.Observer != nil {
+		opt.Observer.OnTaskStart(index)
+	}
+	start := time.Now()
+
+	var res R
+	var err error
+	attempt := 0
+
+retryLoop:
+	for {
+		attempt++
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					if opt.Observer != nil {
+						opt.Observer.OnPanic(index, r)
+					}
+					if opt.PanicAsError {
+						err = &PanicError{Panic: r}
+					} else {
+						panic(r)
+					}
+				}
+			}()
+			res, err = fn(taskCtx, value)
+		}()
+
+		if err == nil || attempt >= maxAttempts || !isRetryable(err, opt.Retry) {
+			break retryLoop
+		}
+
+		select {
+		case <-taskCtx.Done():
+			break retryLoop
+		case <-ctx.Done():
+			break retryLoop
+		case <-time.After(backoffDuration(attempt, opt.Retry)):
+		}
+	}
+
+	if opt.Observer != nil {
+		opt.Observer.OnTaskEnd(index, time.Since(start), err)
+	}
+
+	return res, err, attempt
+}
+
+// isRetryable reports whether err should trigger another attempt. A
+// PanicError is not retried unless Retryable explicitly says otherwise.
+func isRetryable(err error, r RetryPolicy) bool {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) && r.Retryable == nil {
+		return false
+	}
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return true
+}
+
+// backoffDuration returns the wait after the given attempt number (1-based,
+// the attempt that just failed) before the next attempt starts, applying
+// Multiplier growth, MaxBackoff capping, and optional full jitter.
+func backoffDuration(attempt int, r RetryPolicy) time.Duration {
+	if r.InitialBackoff <= 0 {
+		return 0
+	}
+
+	mult := r.Multiplier
+	if mult <= 0 {
+		mult = 1
+	}
+
+	d := float64(r.InitialBackoff) * math.Pow(mult, float64(attempt-1))
+	if r.MaxBackoff > 0 && d > float64(r.MaxBackoff) {
+		d = float64(r.MaxBackoff)
+	}
+	if r.Jitter {
+		d = rand.Float64() * d
+	}
+
+	return time.Duration(d)
+}
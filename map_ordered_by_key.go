@@ -0,0 +1,173 @@
+package orderedpool
+
+import (
+	"context"
+	"sync"
+)
+
+// MapOrderedByKey behaves like MapOrdered, except items are routed to
+// workers by hashKey(value) % opt.Workers instead of to any free worker.
+// Items that hash to the same worker are processed strictly in the order
+// they were submitted, giving callers a happens-before guarantee per key
+// (useful for stateful per-entity handlers) without external locking. The
+// output channel still emits results in global input order. Per-worker
+// queues are bounded by opt.MaxInFlight, and a slow key cannot deadlock the
+// rest of the pool as long as fn respects ctx: reading from input and
+// routing to a worker are split across opt.Workers dispatcher goroutines
+// that take turns pulling the next element (under a mutex, to preserve
+// input order) and then send; sends to one worker's queue keep input order,
+// while sends to different workers are independent, so one dispatcher blocked
+// on a full worker queue never stops the others from feeding idle workers.
+func MapOrderedByKey[T any, R any](
+	ctx context.Context,
+	input <-chan T,
+	hashKey func(T) uint64,
+	fn func(context.Context, T) (R, error),
+	opt Options,
+) <-chan Result[R] {
+	if opt.Workers <= 0 {
+		opt.Workers = 1
+	}
+	if opt.MaxInFlight < opt.Workers {
+		opt.MaxInFlight = opt.Workers
+	}
+
+	queueSize := (opt.MaxInFlight + opt.Workers - 1) / opt.Workers
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	output := make(chan Result[R], opt.MaxInFlight)
+
+	go func() {
+		defer close(output)
+
+		// workCtx lets us stop the dispatchers and workers the moment we
+		// return early (e.g. on EarlyStopN) instead of leaking them until
+		// the caller's ctx is eventually cancelled.
+		workCtx, cancelWork := context.WithCancel(ctx)
+		defer cancelWork()
+
+		// один bounded канал задач на воркер, вместо общего taskChan
+		workerChans := make([]chan indexedTask[T], opt.Workers)
+		for i := range workerChans {
+			workerChans[i] = make(chan indexedTask[T], queueSize)
+		}
+		resultChan := make(chan indexedResult[R], opt.MaxInFlight)
+
+		var wg sync.WaitGroup
+		for i := 0; i < opt.Workers; i++ {
+			wg.Add(1)
+			go worker(workCtx, fn, opt, workerChans[i], resultChan, &wg)
+		}
+
+		go func() {
+			wg.Wait()
+			close(resultChan)
+		}()
+
+		go func() {
+			defer func() {
+				for _, wc := range workerChans {
+					close(wc)
+				}
+			}()
+
+			// Несколько диспетчеров: чтение из input и присвоение индекса
+			// сериализовано через readMu (порядок сохраняется), а отправка в
+			// workerChans[w] делается уже без лока, чтобы один воркер с
+			// полной очередью не блокировал диспетчеризацию остальных.
+			var readMu sync.Mutex
+			var dispatchWG sync.WaitGroup
+			index := 0
+
+			// Очередь ходов на воркер: элемент отправляется в workerChans[w]
+			// только после того, как туда ушёл предыдущий элемент того же w.
+			turns := make([]chan struct{}, opt.Workers)
+			for i := range turns {
+				turns[i] = make(chan struct{})
+				close(turns[i])
+			}
+
+			dispatch := func() {
+				defer dispatchWG.Done()
+
+				for {
+					readMu.Lock()
+					select {
+					case <-workCtx.Done():
+						readMu.Unlock()
+						return
+					case val, ok := <-input:
+						if !ok {
+							readMu.Unlock()
+							return
+						}
+						idx := index
+						index++
+						w := hashKey(val) % uint64(opt.Workers)
+						prev, mine := turns[w], make(chan struct{})
+						turns[w] = mine
+						readMu.Unlock()
+
+						select {
+						case <-workCtx.Done():
+							return
+						case <-prev:
+						}
+						select {
+						case <-workCtx.Done():
+							return
+						case workerChans[w] <- indexedTask[T]{index: idx, value: val}:
+						}
+						close(mine)
+					}
+				}
+			}
+
+			for i := 0; i < opt.Workers; i++ {
+				dispatchWG.Add(1)
+				go dispatch()
+			}
+			dispatchWG.Wait()
+		}()
+
+		buffer := make(map[int]Result[R])
+		nextIndex := 0
+		successCount := 0
+
+		for res := range resultChan {
+			buffer[res.index] = res.res
+
+			if opt.Observer != nil {
+				depth := 0
+				for _, wc := range workerChans {
+					depth += len(wc)
+				}
+				opt.Observer.OnQueueDepth(depth, len(buffer))
+			}
+
+			for {
+				if item, ok := buffer[nextIndex]; ok {
+					delete(buffer, nextIndex)
+					select {
+					case <-workCtx.Done():
+						return
+					case output <- item:
+						if item.Err == nil {
+							successCount++
+							if opt.EarlyStopN > 0 && successCount >= opt.EarlyStopN {
+								return
+							}
+						}
+					}
+					nextIndex++
+				} else {
+					break
+				}
+			}
+		}
+	}()
+
+	return output
+}
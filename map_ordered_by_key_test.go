@@ -0,0 +1,179 @@
+package orderedpool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMapOrderedByKeySequentialPerKey(t *testing.T) {
+	ctx := context.Background()
+
+	in := make(chan int, 12)
+	for i := 0; i < 12; i++ {
+		in <- i
+	}
+	close(in)
+
+	var mu sync.Mutex
+	seenByKey := make(map[int][]int)
+
+	out := MapOrderedByKey(ctx, in, func(x int) uint64 {
+		return uint64(x % 3)
+	}, func(_ context.Context, x int) (int, error) {
+		mu.Lock()
+		seenByKey[x%3] = append(seenByKey[x%3], x)
+		mu.Unlock()
+		return x, nil
+	}, Options{Workers: 3})
+
+	count := 0
+	for r := range out {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		count++
+	}
+	if count != 12 {
+		t.Fatalf("expected 12 results, got %d", count)
+	}
+
+	for key, seen := range seenByKey {
+		for i := 1; i < len(seen); i++ {
+			if seen[i] < seen[i-1] {
+				t.Fatalf("key %d processed out of order: %v", key, seen)
+			}
+		}
+	}
+}
+
+func TestMapOrderedByKeyGlobalOrderPreserved(t *testing.T) {
+	ctx := context.Background()
+
+	in := make(chan int, 6)
+	for i := 0; i < 6; i++ {
+		in <- i
+	}
+	close(in)
+
+	out := MapOrderedByKey(ctx, in, func(x int) uint64 {
+		return uint64(x % 2)
+	}, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 2})
+
+	expected := 0
+	for r := range out {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.Value != expected {
+			t.Fatalf("expected %d, got %d", expected, r.Value)
+		}
+		expected++
+	}
+}
+
+func TestMapOrderedByKeyNoHeadOfLineBlocking(t *testing.T) {
+	ctx := context.Background()
+
+	in := make(chan int)
+
+	slowStarted := make(chan struct{}, 100)
+	releaseSlow := make(chan struct{})
+	fastStarted := make(chan struct{}, 1)
+
+	out := MapOrderedByKey(ctx, in, func(x int) uint64 {
+		if x < 0 {
+			return 0 // always the slow worker
+		}
+		return 1 // always a different, idle worker
+	}, func(_ context.Context, x int) (int, error) {
+		if x < 0 {
+			slowStarted <- struct{}{}
+			<-releaseSlow
+			return x, nil
+		}
+		fastStarted <- struct{}{}
+		return x, nil
+	}, Options{Workers: 2, MaxInFlight: 4})
+
+	go func() {
+		for i := 0; i < 4; i++ {
+			in <- -(i + 1)
+		}
+		in <- 1
+		close(in)
+	}()
+
+	select {
+	case <-slowStarted:
+	case <-time.After(time.Second):
+		t.Fatal("slow task never started")
+	}
+
+	select {
+	case <-fastStarted:
+	case <-time.After(300 * time.Millisecond):
+		t.Fatal("fast-key item starved behind a saturated slow-key worker: head-of-line blocking")
+	}
+
+	close(releaseSlow)
+	for range out {
+	}
+}
+
+func TestMapOrderedByKeyEarlyStopDoesNotLeakGoroutines(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1000)
+	for i := 0; i < 1000; i++ {
+		in <- i
+	}
+	close(in)
+
+	runtime.Gosched()
+	before := runtime.NumGoroutine()
+
+	out := MapOrderedByKey(ctx, in, func(x int) uint64 {
+		return uint64(x % 4)
+	}, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 4, EarlyStopN: 2})
+
+	for range out {
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+1 {
+		t.Fatalf("expected dispatchers/workers to stop after EarlyStopN: before=%d after=%d", before, after)
+	}
+}
+
+func TestMapOrderedByKeyHonorsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan int)
+
+	out := MapOrderedByKey(ctx, in, func(x int) uint64 {
+		return uint64(x)
+	}, func(ctx context.Context, x int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, Options{Workers: 2})
+
+	cancel()
+	close(in)
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("did not expect a result after cancel with no input")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("output channel never closed after cancel")
+	}
+}
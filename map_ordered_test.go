@@ -61,6 +61,32 @@ func TestEarlyStop(t *testing.T) {
 	}
 }
 
+func TestEarlyStopDoesNotLeakGoroutines(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1000)
+	for i := 0; i < 1000; i++ {
+		in <- i
+	}
+	close(in)
+
+	runtime.Gosched()
+	before := runtime.NumGoroutine()
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 4, EarlyStopN: 2})
+
+	for range out {
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+1 {
+		t.Fatalf("expected feeder/workers to stop after EarlyStopN: before=%d after=%d", before, after)
+	}
+}
+
 func TestTimeout(t *testing.T) {
 	ctx := context.Background()
 	in := make(chan int, 1)
@@ -0,0 +1,34 @@
+package orderedpool
+
+import "time"
+
+// Observer receives lifecycle callbacks for tasks processed by MapOrdered,
+// MapOrderedByKey, or a Pool, so callers can wire this package into metrics
+// systems such as Prometheus or OpenTelemetry without forking it. All
+// callbacks are optional to implement meaningfully; a nil Observer on
+// Options disables instrumentation entirely.
+type Observer interface {
+	// OnTaskStart fires once a task begins its first attempt.
+	OnTaskStart(index int)
+	// OnTaskEnd fires once a task's final attempt has settled, with the
+	// total duration across all attempts and the final error (nil on
+	// success).
+	OnTaskEnd(index int, dur time.Duration, err error)
+	// OnPanic fires every time an attempt recovers from a panic, even if a
+	// later retry succeeds.
+	OnPanic(index int, v any)
+	// OnQueueDepth reports a point-in-time snapshot of how many tasks are
+	// queued for a worker (inFlight) versus held in the reordering buffer
+	// waiting for an earlier element to complete (buffered).
+	OnQueueDepth(inFlight, buffered int)
+}
+
+// Stats is a lightweight snapshot of a Pool's counters, safe to read
+// concurrently with the pool's workers.
+type Stats struct {
+	Completed int64
+	Failed    int64
+	Panicked  int64
+	Retried   int64
+	InFlight  int64
+}
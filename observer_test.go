@@ -0,0 +1,125 @@
+package orderedpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	mu         sync.Mutex
+	starts     []int
+	ends       []int
+	panics     []int
+	queueDepth int
+}
+
+func (o *recordingObserver) OnTaskStart(index int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.starts = append(o.starts, index)
+}
+
+func (o *recordingObserver) OnTaskEnd(index int, _ time.Duration, _ error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ends = append(o.ends, index)
+}
+
+func (o *recordingObserver) OnPanic(index int, _ any) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.panics = append(o.panics, index)
+}
+
+func (o *recordingObserver) OnQueueDepth(_, buffered int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.queueDepth = buffered
+}
+
+func TestObserverReceivesTaskLifecycle(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	obs := &recordingObserver{}
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 2, Observer: obs})
+
+	for range out {
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.starts) != 3 || len(obs.ends) != 3 {
+		t.Fatalf("expected 3 starts/ends, got %d/%d", len(obs.starts), len(obs.ends))
+	}
+}
+
+func TestObserverOnPanic(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	obs := &recordingObserver{}
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		panic("boom")
+	}, Options{Workers: 1, PanicAsError: true, Observer: obs})
+
+	<-out
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.panics) != 1 {
+		t.Fatalf("expected 1 panic callback, got %d", len(obs.panics))
+	}
+}
+
+func TestPoolStatsTracksOutcomes(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		if x < 0 {
+			return 0, errBoom
+		}
+		return x, nil
+	}, Options{Workers: 2})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(context.Background())
+
+	var outs []<-chan Result[int]
+	for _, v := range []int{1, 2, -1} {
+		out, err := p.Submit(nil, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outs = append(outs, out)
+	}
+	for _, out := range outs {
+		<-out
+	}
+
+	stats := p.Stats()
+	if stats.Completed != 2 {
+		t.Fatalf("expected 2 completed, got %d", stats.Completed)
+	}
+	if stats.Failed != 1 {
+		t.Fatalf("expected 1 failed, got %d", stats.Failed)
+	}
+	if stats.InFlight != 0 {
+		t.Fatalf("expected 0 in-flight after completion, got %d", stats.InFlight)
+	}
+}
@@ -0,0 +1,169 @@
+package orderedpool
+
+import (
+	"context"
+	"sync"
+)
+
+// indexedStageTask carries the upstream Result (possibly already failed) into
+// the next stage so that errors can be forwarded without invoking fn.
+type indexedStageTask[In any] struct {
+	index int
+	res   Result[In]
+}
+
+// NewPipeline wraps input as the first stage of a pipeline, in the same
+// order-preserving form every Stage call expects. It does no work of its own
+// besides indexing elements, so opt.Workers/MaxInFlight are mostly irrelevant
+// here but kept for symmetry with Stage.
+func NewPipeline[In any](ctx context.Context, input <-chan In, opt Options) <-chan Result[In] {
+	return MapOrdered(ctx, input, func(_ context.Context, v In) (In, error) {
+		return v, nil
+	}, opt)
+}
+
+// Stage appends a typed processing step to a pipeline built with NewPipeline.
+// An element that already failed in an earlier stage is forwarded as
+// Result{Err: ...} without calling fn, while global input order is
+// preserved. opt.Retry and opt.Observer are honored exactly as they are in
+// MapOrdered (a short-circuited element never invokes fn, so it is neither
+// retried nor reported to the Observer). opt.EarlyStopN is also honored: the
+// stage stops emitting once that many successful results have been sent,
+// same as every other entry point.
+func Stage[In, Out any](
+	ctx context.Context,
+	prev <-chan Result[In],
+	fn func(context.Context, In) (Out, error),
+	opt Options,
+) <-chan Result[Out] {
+	if opt.Workers <= 0 {
+		opt.Workers = 1
+	}
+	if opt.MaxInFlight < opt.Workers {
+		opt.MaxInFlight = opt.Workers
+	}
+
+	output := make(chan Result[Out], opt.MaxInFlight)
+
+	go func() {
+		defer close(output)
+
+		// workCtx lets us stop the feeder and workers the moment we return
+		// early (e.g. on EarlyStopN) instead of leaking them until the
+		// caller's ctx is eventually cancelled.
+		workCtx, cancelWork := context.WithCancel(ctx)
+		defer cancelWork()
+
+		taskChan := make(chan indexedStageTask[In], opt.MaxInFlight)
+		resultChan := make(chan indexedResult[Out], opt.MaxInFlight)
+
+		var wg sync.WaitGroup
+		for i := 0; i < opt.Workers; i++ {
+			wg.Add(1)
+			go stageWorker(workCtx, fn, opt, taskChan, resultChan, &wg)
+		}
+
+		go func() {
+			wg.Wait()
+			close(resultChan)
+		}()
+
+		go func() {
+			defer close(taskChan)
+
+			index := 0
+			for res := range prev {
+				select {
+				case <-workCtx.Done():
+					return
+				case taskChan <- indexedStageTask[In]{index: index, res: res}:
+					index++
+				}
+			}
+		}()
+
+		buffer := make(map[int]Result[Out])
+		nextIndex := 0
+		successCount := 0
+
+		for res := range resultChan {
+			buffer[res.index] = res.res
+
+			if opt.Observer != nil {
+				opt.Observer.OnQueueDepth(len(taskChan), len(buffer))
+			}
+
+			for {
+				if item, ok := buffer[nextIndex]; ok {
+					delete(buffer, nextIndex)
+					select {
+					case <-workCtx.Done():
+						return
+					case output <- item:
+						if item.Err == nil {
+							successCount++
+							if opt.EarlyStopN > 0 && successCount >= opt.EarlyStopN {
+								return
+							}
+						}
+					}
+					nextIndex++
+				} else {
+					break
+				}
+			}
+		}
+	}()
+
+	return output
+}
+
+func stageWorker[In, Out any](
+	ctx context.Context,
+	fn func(context.Context, In) (Out, error),
+	opt Options,
+	taskChan <-chan indexedStageTask[In],
+	resultChan chan<- indexedResult[Out],
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task, ok := <-taskChan:
+			if !ok {
+				return
+			}
+
+			// ошибка из предыдущего этапа — пропускаем fn, но сохраняем позицию
+			if task.res.Err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case resultChan <- indexedResult[Out]{index: task.index, res: Result[Out]{Err: task.res.Err}}:
+				}
+				continue
+			}
+
+			taskCtx := ctx
+			var cancel context.CancelFunc
+			if opt.TaskTimeout > 0 {
+				taskCtx, cancel = context.WithTimeout(ctx, opt.TaskTimeout)
+			}
+
+			out, err, attempts := runWithRetry(ctx, taskCtx, fn, opt, task.res.Value, task.index)
+
+			if cancel != nil {
+				cancel()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case resultChan <- indexedResult[Out]{index: task.index, res: Result[Out]{Value: out, Err: err, Attempts: attempts}}:
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package orderedpool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPipelineBasic(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	p := NewPipeline(ctx, in, Options{Workers: 2})
+	s1 := Stage(ctx, p, func(_ context.Context, x int) (int, error) {
+		return x * 2, nil
+	}, Options{Workers: 2})
+	out := Stage(ctx, s1, func(_ context.Context, x int) (string, error) {
+		return time.Duration(x).String(), nil
+	}, Options{Workers: 2})
+
+	expected := []string{"2ns", "4ns", "6ns"}
+	i := 0
+	for r := range out {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.Value != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], r.Value)
+		}
+		i++
+	}
+}
+
+func TestPipelineShortCircuitsOnError(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 3)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+
+	errBoom := errors.New("boom")
+	called := make(chan int, 3)
+
+	p := NewPipeline(ctx, in, Options{Workers: 1})
+	s1 := Stage(ctx, p, func(_ context.Context, x int) (int, error) {
+		if x == 2 {
+			return 0, errBoom
+		}
+		return x, nil
+	}, Options{Workers: 1})
+	out := Stage(ctx, s1, func(_ context.Context, x int) (int, error) {
+		called <- x
+		return x * 10, nil
+	}, Options{Workers: 1})
+
+	var results []Result[int]
+	for r := range out {
+		results = append(results, r)
+	}
+	close(called)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[1].Err != errBoom {
+		t.Fatalf("expected short-circuited error at index 1, got %v", results[1])
+	}
+	if results[0].Value != 10 || results[2].Value != 30 {
+		t.Fatalf("unexpected values: %+v", results)
+	}
+	for x := range called {
+		if x == 2 {
+			t.Fatalf("second stage must not run for the failed element")
+		}
+	}
+}
+
+func TestPipelineBackpressure(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int)
+
+	started := make(chan struct{}, 100)
+	release := make(chan struct{})
+
+	p := NewPipeline(ctx, in, Options{Workers: 1, MaxInFlight: 1})
+	out := Stage(ctx, p, func(_ context.Context, x int) (int, error) {
+		started <- struct{}{}
+		<-release
+		return x, nil
+	}, Options{Workers: 1, MaxInFlight: 1})
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("first item never started")
+	}
+
+	select {
+	case <-started:
+		t.Fatal("second item started before first was released: no backpressure")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for range out {
+	}
+}
+
+func TestStageHonorsRetry(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	var calls int32
+	errTransient := errors.New("transient")
+
+	p := NewPipeline(ctx, in, Options{Workers: 1})
+	out := Stage(ctx, p, func(_ context.Context, x int) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n < 3 {
+			return 0, errTransient
+		}
+		return x, nil
+	}, Options{
+		Workers: 1,
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			Multiplier:     2,
+		},
+	})
+
+	r := <-out
+	if r.Err != nil {
+		t.Fatalf("expected eventual success, got %v", r.Err)
+	}
+	if r.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", r.Attempts)
+	}
+}
+
+func TestStageReportsToObserver(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 2)
+	in <- 1
+	in <- 2
+	close(in)
+
+	obs := &recordingObserver{}
+
+	p := NewPipeline(ctx, in, Options{Workers: 1})
+	out := Stage(ctx, p, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 1, Observer: obs})
+
+	for range out {
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.starts) != 2 || len(obs.ends) != 2 {
+		t.Fatalf("expected 2 starts/ends, got %d/%d", len(obs.starts), len(obs.ends))
+	}
+}
+
+func TestStageHonorsEarlyStopN(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 5)
+	for i := 1; i <= 5; i++ {
+		in <- i
+	}
+	close(in)
+
+	p := NewPipeline(ctx, in, Options{Workers: 2})
+	out := Stage(ctx, p, func(_ context.Context, x int) (int, error) {
+		return x * 2, nil
+	}, Options{Workers: 2, EarlyStopN: 2})
+
+	count := 0
+	for r := range out {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		count++
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 results, got %d", count)
+	}
+}
+
+func TestStageEarlyStopDoesNotLeakGoroutines(t *testing.T) {
+	ctx := context.Background()
+	prev := make(chan Result[int], 1000)
+	for i := 0; i < 1000; i++ {
+		prev <- Result[int]{Value: i}
+	}
+	close(prev)
+
+	runtime.Gosched()
+	before := runtime.NumGoroutine()
+
+	out := Stage(ctx, prev, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 4, EarlyStopN: 2})
+
+	for range out {
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+1 {
+		t.Fatalf("expected feeder/workers to stop after EarlyStopN: before=%d after=%d", before, after)
+	}
+}
+
+func TestPipelineNoGoroutineLeakOnCancel(t *testing.T) {
+	start := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+
+	p := NewPipeline(ctx, in, Options{Workers: 2})
+	s1 := Stage(ctx, p, func(ctx context.Context, x int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, Options{Workers: 2})
+	_ = Stage(ctx, s1, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 2})
+
+	cancel()
+	close(in)
+
+	time.Sleep(20 * time.Millisecond)
+	end := runtime.NumGoroutine()
+
+	if end > start+1 {
+		t.Fatalf("goroutine leak: %d -> %d", start, end)
+	}
+}
@@ -0,0 +1,348 @@
+package orderedpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAlreadyStarted is returned by Start when the pool is already running.
+var ErrAlreadyStarted = errors.New("orderedpool: pool already started")
+
+// ErrAlreadyStopped is returned by Stop when the pool was never started or
+// has already been stopped, and by Submit once the pool is no longer
+// accepting work.
+var ErrAlreadyStopped = errors.New("orderedpool: pool already stopped")
+
+type poolTask[T any, R any] struct {
+	ctx   context.Context
+	value T
+	out   chan Result[R]
+	done  chan struct{}
+	seq   int
+}
+
+type poolStats struct {
+	completed int64
+	failed    int64
+	panicked  int64
+	retried   int64
+	inFlight  int64
+}
+
+// keyQueue is a per-key submission queue plus a pending count tracking how
+// many of its tasks are still queued or in flight. Once pending drops to
+// zero, the owning runKey goroutine removes it from Pool.keys and exits,
+// so an idle key costs nothing once its work has drained.
+type keyQueue[T any, R any] struct {
+	queue   chan poolTask[T, R]
+	pending int
+}
+
+// Pool is a long-lived, Service-style worker pool: start it once, Submit work
+// from as many callers as you like, and Stop it when the process shuts down.
+// Unlike MapOrdered it does not own an input channel; pool lifetime is
+// decoupled from any single caller's request lifetime.
+//
+// Submissions sharing the same batch key are guaranteed to complete in
+// submission order; submissions under different keys (or a nil key) may
+// interleave freely across the worker pool.
+type Pool[T any, R any] struct {
+	fn  func(context.Context, T) (R, error)
+	opt Options
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	tasks chan poolTask[T, R]
+	seq   int64
+
+	keysMu sync.Mutex
+	keys   map[any]*keyQueue[T, R]
+
+	stats poolStats
+}
+
+// NewPool creates a Pool that will run fn on opt.Workers workers once Start
+// is called.
+func NewPool[T any, R any](fn func(context.Context, T) (R, error), opt Options) *Pool[T, R] {
+	if opt.Workers <= 0 {
+		opt.Workers = 1
+	}
+	if opt.MaxInFlight < opt.Workers {
+		opt.MaxInFlight = opt.Workers
+	}
+
+	return &Pool[T, R]{
+		fn:    fn,
+		opt:   opt,
+		tasks: make(chan poolTask[T, R], opt.MaxInFlight),
+		keys:  make(map[any]*keyQueue[T, R]),
+	}
+}
+
+// Start launches the pool's workers. It returns ErrAlreadyStarted if called
+// more than once.
+func (p *Pool[T, R]) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.started {
+		return ErrAlreadyStarted
+	}
+	p.started = true
+
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	for i := 0; i < p.opt.Workers; i++ {
+		p.wg.Add(1)
+		go p.worker(p.ctx)
+	}
+
+	return nil
+}
+
+// Stop cancels all in-flight work and waits for workers to exit, up to ctx's
+// deadline. It returns ErrAlreadyStopped if the pool was never started or has
+// already been stopped. Any task still sitting in a queue once cancellation
+// takes effect — rather than already in a worker's hands — is delivered
+// a Result whose Err is context.Canceled (the pool's own context, not ctx)
+// and its out channel closed, so a Submit made just
+// before Stop never blocks its caller forever.
+func (p *Pool[T, R]) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.started || p.stopped {
+		p.mu.Unlock()
+		return ErrAlreadyStopped
+	}
+	p.stopped = true
+	cancel := p.cancel
+	p.mu.Unlock()
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.drainAbandoned()
+		return nil
+	case <-ctx.Done():
+		p.drainAbandoned()
+		return ctx.Err()
+	}
+}
+
+// drainAbandoned completes every task left sitting in p.tasks or a keyQueue
+// after cancellation, the same way enqueueKeyed's own ctx.Done() branch
+// already does for a task that never made it into a queue at all.
+func (p *Pool[T, R]) drainAbandoned() {
+	err := p.ctx.Err()
+
+	p.drainQueue(p.tasks, err)
+
+	p.keysMu.Lock()
+	remaining := p.keys
+	p.keys = make(map[any]*keyQueue[T, R])
+	p.keysMu.Unlock()
+
+	for _, kq := range remaining {
+		p.drainQueue(kq.queue, err)
+	}
+}
+
+// drainQueue abandons every task currently buffered in ch without blocking;
+// it stops as soon as ch has nothing left to receive.
+func (p *Pool[T, R]) drainQueue(ch chan poolTask[T, R], err error) {
+	for {
+		select {
+		case task := <-ch:
+			task.out <- Result[R]{Err: err}
+			close(task.out)
+			if task.done != nil {
+				close(task.done)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Stats returns a point-in-time snapshot of the pool's counters. It is safe
+// to call concurrently with Submit and the pool's workers.
+func (p *Pool[T, R]) Stats() Stats {
+	return Stats{
+		Completed: atomic.LoadInt64(&p.stats.completed),
+		Failed:    atomic.LoadInt64(&p.stats.failed),
+		Panicked:  atomic.LoadInt64(&p.stats.panicked),
+		Retried:   atomic.LoadInt64(&p.stats.retried),
+		InFlight:  atomic.LoadInt64(&p.stats.inFlight),
+	}
+}
+
+// Submit queues value for processing and returns a channel that will receive
+// exactly one Result. When key is non-nil, results for the same key are
+// delivered in the order they were submitted.
+func (p *Pool[T, R]) Submit(key any, value T) (<-chan Result[R], error) {
+	p.mu.Lock()
+	if !p.started || p.stopped {
+		p.mu.Unlock()
+		return nil, ErrAlreadyStopped
+	}
+	ctx := p.ctx
+	p.mu.Unlock()
+
+	out := make(chan Result[R], 1)
+	seq := int(atomic.AddInt64(&p.seq, 1)) - 1
+	task := poolTask[T, R]{ctx: ctx, value: value, out: out, seq: seq}
+
+	if key == nil {
+		select {
+		case p.tasks <- task:
+		case <-ctx.Done():
+			out <- Result[R]{Err: ctx.Err()}
+			close(out)
+		}
+		return out, nil
+	}
+
+	p.enqueueKeyed(ctx, key, task)
+	return out, nil
+}
+
+func (p *Pool[T, R]) enqueueKeyed(ctx context.Context, key any, task poolTask[T, R]) {
+	p.keysMu.Lock()
+	kq, ok := p.keys[key]
+	if !ok {
+		kq = &keyQueue[T, R]{queue: make(chan poolTask[T, R], p.opt.MaxInFlight)}
+		p.keys[key] = kq
+		p.wg.Add(1)
+		go p.runKey(ctx, key, kq)
+	}
+	kq.pending++
+	p.keysMu.Unlock()
+
+	select {
+	case kq.queue <- task:
+	case <-ctx.Done():
+		p.keysMu.Lock()
+		kq.pending--
+		p.keysMu.Unlock()
+		task.out <- Result[R]{Err: ctx.Err()}
+		close(task.out)
+	}
+}
+
+// runKey serializes delivery of one key's tasks to the shared worker pool:
+// it only dispatches the next queued task once the previous one has
+// completed, so in-key order is preserved without blocking other keys. Once
+// the key's pending count drops to zero (its queue has drained and nothing
+// new has been submitted in the meantime), runKey removes its entry from
+// Pool.keys and exits, so a key that stops being used doesn't leak a
+// goroutine or channel for the rest of the pool's lifetime.
+func (p *Pool[T, R]) runKey(ctx context.Context, key any, kq *keyQueue[T, R]) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task, ok := <-kq.queue:
+			if !ok {
+				return
+			}
+
+			task.done = make(chan struct{})
+
+			select {
+			case p.tasks <- task:
+			case <-ctx.Done():
+				task.out <- Result[R]{Err: ctx.Err()}
+				close(task.out)
+				return
+			}
+
+			select {
+			case <-task.done:
+			case <-ctx.Done():
+				return
+			}
+
+			p.keysMu.Lock()
+			kq.pending--
+			if kq.pending == 0 {
+				delete(p.keys, key)
+				p.keysMu.Unlock()
+				return
+			}
+			p.keysMu.Unlock()
+		}
+	}
+}
+
+func (p *Pool[T, R]) worker(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task, ok := <-p.tasks:
+			if !ok {
+				return
+			}
+
+			// задача взята уже после отмены — не запускаем fn, а отдаём ошибку
+			if ctx.Err() != nil {
+				task.out <- Result[R]{Err: ctx.Err()}
+				close(task.out)
+				if task.done != nil {
+					close(task.done)
+				}
+				continue
+			}
+
+			taskCtx := task.ctx
+			var cancel context.CancelFunc
+			if p.opt.TaskTimeout > 0 {
+				taskCtx, cancel = context.WithTimeout(task.ctx, p.opt.TaskTimeout)
+			}
+
+			atomic.AddInt64(&p.stats.inFlight, 1)
+			res, err, attempts := runWithRetry(ctx, taskCtx, p.fn, p.opt, task.value, task.seq)
+			atomic.AddInt64(&p.stats.inFlight, -1)
+
+			if cancel != nil {
+				cancel()
+			}
+
+			if attempts > 1 {
+				atomic.AddInt64(&p.stats.retried, int64(attempts-1))
+			}
+			if err != nil {
+				atomic.AddInt64(&p.stats.failed, 1)
+				var panicErr *PanicError
+				if errors.As(err, &panicErr) {
+					atomic.AddInt64(&p.stats.panicked, 1)
+				}
+			} else {
+				atomic.AddInt64(&p.stats.completed, 1)
+			}
+
+			task.out <- Result[R]{Value: res, Err: err, Attempts: attempts}
+			close(task.out)
+			if task.done != nil {
+				close(task.done)
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package orderedpool
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPoolStartStopLifecycle(t *testing.T) {
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 2})
+
+	if err := p.Start(); err != nil {
+		t.Fatalf("unexpected error starting pool: %v", err)
+	}
+	if err := p.Start(); err != ErrAlreadyStarted {
+		t.Fatalf("expected ErrAlreadyStarted, got %v", err)
+	}
+
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error stopping pool: %v", err)
+	}
+	if err := p.Stop(context.Background()); err != ErrAlreadyStopped {
+		t.Fatalf("expected ErrAlreadyStopped, got %v", err)
+	}
+
+	if _, err := p.Submit(nil, 1); err != ErrAlreadyStopped {
+		t.Fatalf("expected Submit on stopped pool to fail, got %v", err)
+	}
+}
+
+func TestPoolSubmitBasic(t *testing.T) {
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		return x * 2, nil
+	}, Options{Workers: 2})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(context.Background())
+
+	out, err := p.Submit(nil, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-out:
+		if r.Err != nil || r.Value != 10 {
+			t.Fatalf("unexpected result: %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("submit never completed")
+	}
+}
+
+func TestPoolPreservesOrderWithinKey(t *testing.T) {
+	var mu orderGuard
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		mu.observe(x)
+		time.Sleep(time.Millisecond)
+		return x, nil
+	}, Options{Workers: 4})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(context.Background())
+
+	outs := make([]<-chan Result[int], 5)
+	for i := 0; i < 5; i++ {
+		out, err := p.Submit("same-key", i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outs[i] = out
+	}
+
+	for i, out := range outs {
+		r := <-out
+		if r.Err != nil || r.Value != i {
+			t.Fatalf("expected %d, got %+v", i, r)
+		}
+	}
+
+	mu.assertOrdered(t)
+}
+
+func TestPoolReapsIdleKeyQueues(t *testing.T) {
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 4})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop(context.Background())
+
+	runtime.Gosched()
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 500; i++ {
+		out, err := p.Submit(fmt.Sprintf("key-%d", i), i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := <-out; r.Err != nil || r.Value != i {
+			t.Fatalf("unexpected result: %+v", r)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+2 {
+		t.Fatalf("expected idle per-key goroutines to be reaped: before=%d after=%d", before, after)
+	}
+
+	p.keysMu.Lock()
+	remaining := len(p.keys)
+	p.keysMu.Unlock()
+
+	if remaining != 0 {
+		t.Fatalf("expected no leftover key queues, got %d", remaining)
+	}
+}
+
+func TestPoolStopDrainsQueuedTasks(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 5)
+
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		started <- struct{}{}
+		<-release
+		return x, nil
+	}, Options{Workers: 1, MaxInFlight: 10})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var outs []<-chan Result[int]
+	for i := 0; i < 5; i++ {
+		out, err := p.Submit(nil, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outs = append(outs, out)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("first task never started")
+	}
+
+	stopErr := make(chan error, 1)
+	go func() {
+		stopErr <- p.Stop(context.Background())
+	}()
+
+	close(release)
+
+	for i, out := range outs {
+		select {
+		case <-out:
+		case <-time.After(time.Second):
+			t.Fatalf("submission %d never received a result after Stop", i)
+		}
+	}
+
+	if err := <-stopErr; err != nil {
+		t.Fatalf("unexpected Stop error: %v", err)
+	}
+}
+
+func TestPoolStopDrainsQueuedKeyedTasks(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 5)
+
+	p := NewPool(func(_ context.Context, x int) (int, error) {
+		started <- struct{}{}
+		<-release
+		return x, nil
+	}, Options{Workers: 1, MaxInFlight: 10})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var outs []<-chan Result[int]
+	for i := 0; i < 5; i++ {
+		out, err := p.Submit("same-key", i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outs = append(outs, out)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("first task never started")
+	}
+
+	stopErr := make(chan error, 1)
+	go func() {
+		stopErr <- p.Stop(context.Background())
+	}()
+
+	close(release)
+
+	for i, out := range outs {
+		select {
+		case <-out:
+		case <-time.After(time.Second):
+			t.Fatalf("keyed submission %d never received a result after Stop", i)
+		}
+	}
+
+	if err := <-stopErr; err != nil {
+		t.Fatalf("unexpected Stop error: %v", err)
+	}
+}
+
+// orderGuard records the order in which values were observed by the pool fn
+// so the test can assert they ran strictly in submission order for one key.
+type orderGuard struct {
+	seen []int
+}
+
+func (g *orderGuard) observe(x int) {
+	g.seen = append(g.seen, x)
+}
+
+func (g *orderGuard) assertOrdered(t *testing.T) {
+	t.Helper()
+	for i, v := range g.seen {
+		if v != i {
+			t.Fatalf("expected in-key sequential execution, got order %v", g.seen)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package orderedpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetrySucceedsAfterTransientErrors(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	var calls int32
+	errTransient := errors.New("transient")
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n < 3 {
+			return 0, errTransient
+		}
+		return x, nil
+	}, Options{
+		Workers: 1,
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			Multiplier:     2,
+		},
+	})
+
+	r := <-out
+	if r.Err != nil {
+		t.Fatalf("expected eventual success, got %v", r.Err)
+	}
+	if r.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", r.Attempts)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	errAlways := errors.New("always fails")
+	var calls int32
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errAlways
+	}, Options{
+		Workers: 1,
+		Retry: RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		},
+	})
+
+	r := <-out
+	if r.Err != errAlways {
+		t.Fatalf("expected final error to surface, got %v", r.Err)
+	}
+	if r.Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", r.Attempts)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Fatalf("expected fn called 3 times, got %d", calls)
+	}
+}
+
+func TestRetryRetryableClassifierStopsEarly(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	errFatal := errors.New("do not retry me")
+	var calls int32
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errFatal
+	}, Options{
+		Workers: 1,
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			Retryable:      func(err error) bool { return err != errFatal },
+		},
+	})
+
+	r := <-out
+	if r.Err != errFatal {
+		t.Fatalf("expected errFatal, got %v", r.Err)
+	}
+	if r.Attempts != 1 {
+		t.Fatalf("expected a single attempt for a non-retryable error, got %d", r.Attempts)
+	}
+}
+
+func TestRetryPanicNotRetriedByDefault(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 1)
+	in <- 1
+	close(in)
+
+	var calls int32
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		panic("boom")
+	}, Options{
+		Workers:      1,
+		PanicAsError: true,
+		Retry: RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+		},
+	})
+
+	r := <-out
+	if _, ok := r.Err.(*PanicError); !ok {
+		t.Fatalf("expected PanicError, got %v", r.Err)
+	}
+	if r.Attempts != 1 {
+		t.Fatalf("expected panic to not be retried, got %d attempts", r.Attempts)
+	}
+}
+
+func TestRetryEarlyStopStillWorks(t *testing.T) {
+	ctx := context.Background()
+	in := make(chan int, 4)
+	for i := 1; i <= 4; i++ {
+		in <- i
+	}
+	close(in)
+
+	out := MapOrdered(ctx, in, func(_ context.Context, x int) (int, error) {
+		return x, nil
+	}, Options{Workers: 2, EarlyStopN: 2, Retry: RetryPolicy{MaxAttempts: 3}})
+
+	count := 0
+	for r := range out {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("expected EarlyStopN to still cap at 2, got %d", count)
+	}
+}